@@ -0,0 +1,126 @@
+package main
+
+import (
+	"log"
+	"sync"
+
+	"github.com/mmalchuk/glide-update/internal/importers/glide"
+)
+
+// mirrorTask is one unit of work fed to the worker pool: mirror a single
+// locked dependency. index is its position in locks.Imports/DevImports,
+// so results can be put back in the same order regardless of which
+// goroutine finishes first.
+type mirrorTask struct {
+	lock  *glide.Lock
+	dev   bool
+	index int
+}
+
+// mirrorResult is what a worker reports back for a mirrorTask.
+type mirrorResult struct {
+	dev   bool
+	index int
+	dep   *glide.Dependency
+	entry ReportEntry
+	err   error
+}
+
+// mirrorAll pushes every locked dependency to GitLab (or resolves it
+// through mirrors) using a bounded pool of concurrentWorkers goroutines.
+// Project creation is serialized through createMu so two workers never
+// race to create the same namespace project. A failing dependency is
+// logged and skipped rather than aborting the rest of the batch. Under
+// dryRun nothing is mutated; the returned Report records what would have
+// happened. Results are reassembled in locks.Imports/DevImports order, so
+// output doesn't depend on goroutine scheduling.
+func mirrorAll(client gitLabAPI, projects map[string]string, glideCachePath string, groupID int, mirrors Mirrors, locks *glide.Lockfile, concurrentWorkers int, dryRun bool) (imports glide.Dependencies, devImports glide.Dependencies, report *Report) {
+
+	tasks := make(chan mirrorTask)
+	results := make(chan mirrorResult)
+
+	var createMu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrentWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range tasks {
+				results <- mirrorWorker(client, projects, glideCachePath, groupID, mirrors, &createMu, dryRun, task)
+			}
+		}()
+	}
+
+	go func() {
+		for index, lock := range locks.Imports {
+			tasks <- mirrorTask{lock: lock, index: index}
+		}
+		for index, lock := range locks.DevImports {
+			tasks <- mirrorTask{lock: lock, dev: true, index: index}
+		}
+		close(tasks)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	importResults := make([]*mirrorResult, len(locks.Imports))
+	devImportResults := make([]*mirrorResult, len(locks.DevImports))
+
+	for result := range results {
+		result := result
+		if result.err != nil {
+			log.Printf("- failed to mirror dependency: %v", result.err)
+		}
+		if result.dev {
+			devImportResults[result.index] = &result
+		} else {
+			importResults[result.index] = &result
+		}
+	}
+
+	report = &Report{}
+	for _, result := range importResults {
+		report.Imports = append(report.Imports, result.entry)
+		if result.dep != nil {
+			imports = append(imports, result.dep)
+		}
+	}
+	for _, result := range devImportResults {
+		report.DevImports = append(report.DevImports, result.entry)
+		if result.dep != nil {
+			devImports = append(devImports, result.dep)
+		}
+	}
+
+	return imports, devImports, report
+}
+
+func mirrorWorker(client gitLabAPI, projects map[string]string, glideCachePath string, groupID int, mirrors Mirrors, createMu *sync.Mutex, dryRun bool, task mirrorTask) mirrorResult {
+
+	remoteURL, vcsType, entry, err := processGlideCache(client, task.lock.Name, task.lock.Repository, projects, glideCachePath, groupID, mirrors, createMu, dryRun)
+	entry.Version = task.lock.Version
+	if err != nil {
+		entry.Error = err.Error()
+		return mirrorResult{dev: task.dev, index: task.index, entry: entry, err: err}
+	}
+	if remoteURL == "" {
+		return mirrorResult{dev: task.dev, index: task.index, entry: entry}
+	}
+
+	return mirrorResult{
+		dev:   task.dev,
+		index: task.index,
+		entry: entry,
+		dep: &glide.Dependency{
+			Name:        task.lock.Name,
+			Reference:   task.lock.Version,
+			Repository:  remoteURL,
+			VcsType:     vcsType,
+			Subpackages: task.lock.Subpackages,
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mmalchuk/glide-update/internal/importers/glide"
+)
+
+// TestMirrorAllPreservesLockOrder exercises mirrorAll's worker pool with
+// real concurrency (enough workers that goroutines finish out of order)
+// and checks that imports/report entries still come back in
+// locks.Imports order, pinning the a6e7b20/682a798 index-based result
+// reassembly. Every dependency is configured as a mirror, so
+// processGlideCache returns before touching git, the shared projects map
+// or a real GitLab client, keeping this test hermetic; the guarded reads
+// of that map are not exercised here.
+func TestMirrorAllPreservesLockOrder(t *testing.T) {
+	const n = 20
+
+	locks := &glide.Lockfile{}
+	var mirrors Mirrors
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("example.com/dep%02d", i)
+		locks.Imports = append(locks.Imports, &glide.Lock{Name: name, Version: fmt.Sprintf("v%d", i)})
+		mirrors = append(mirrors, &Mirror{Original: name, Repo: "https://mirror.example.com/" + name})
+	}
+
+	imports, _, report := mirrorAll(nil, map[string]string{}, "/nonexistent/", 0, mirrors, locks, 8, false)
+
+	if len(imports) != n {
+		t.Fatalf("len(imports) = %d, want %d", len(imports), n)
+	}
+	if len(report.Imports) != n {
+		t.Fatalf("len(report.Imports) = %d, want %d", len(report.Imports), n)
+	}
+
+	for i, dep := range imports {
+		want := locks.Imports[i].Name
+		if dep.Name != want {
+			t.Errorf("imports[%d].Name = %q, want %q (lock order not preserved)", i, dep.Name, want)
+		}
+		if report.Imports[i].Name != want {
+			t.Errorf("report.Imports[%d].Name = %q, want %q (lock order not preserved)", i, report.Imports[i].Name, want)
+		}
+	}
+}
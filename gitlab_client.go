@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// gitLabAPIVersion selects which GitLab REST API generation to target.
+type gitLabAPIVersion string
+
+const (
+	gitLabAPIV3   gitLabAPIVersion = "v3"
+	gitLabAPIV4   gitLabAPIVersion = "v4"
+	gitLabAPIAuto gitLabAPIVersion = "auto"
+)
+
+// gitLabAPI abstracts the group/project operations this tool needs, so that
+// both the legacy v3 and the current v4 GitLab REST API can be targeted.
+type gitLabAPI interface {
+	getGroupID(groupName string) (int, error)
+	listGroupProjects(groupID int) (map[string]string, error)
+	createGroupProject(projectName string, groupID int) (string, error)
+}
+
+// newGitLabAPI builds a gitLabAPI for the requested version. In auto mode it
+// probes /api/v4/version with the private token and falls back to v3 if the
+// endpoint isn't found.
+func newGitLabAPI(version gitLabAPIVersion, baseURL, privateToken string) (gitLabAPI, error) {
+	switch version {
+	case gitLabAPIV3:
+		return newGitLabClientV3(baseURL, privateToken), nil
+	case gitLabAPIV4:
+		return newGitLabClientV4(baseURL, privateToken), nil
+	case gitLabAPIAuto:
+		if probeGitLabV4(baseURL, privateToken) {
+			log.Printf("- detected GitLab API v4 at '%s'", baseURL)
+			return newGitLabClientV4(baseURL, privateToken), nil
+		}
+		log.Printf("- GitLab API v4 not found at '%s', falling back to v3", baseURL)
+		return newGitLabClientV3(baseURL, privateToken), nil
+	default:
+		return nil, fmt.Errorf("unknown --gitlab-api-version %q", version)
+	}
+}
+
+// probeGitLabV4 reports whether baseURL serves the v4 API, by requesting
+// /api/v4/version with the given private token. Only a 404 (or a request
+// that fails outright) counts as no v4.
+func probeGitLabV4(baseURL, privateToken string) bool {
+	req, err := http.NewRequest("GET", baseURL+"/api/v4/version", nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("PRIVATE-TOKEN", privateToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode != http.StatusNotFound
+}
+
+// gitLabClientV3 talks to the legacy GitLab v3 REST API.
+type gitLabClientV3 struct {
+	client *gitlab.Client
+}
+
+func newGitLabClientV3(baseURL, privateToken string) *gitLabClientV3 {
+	client := gitlab.NewClient(nil, privateToken)
+	client.SetBaseURL(baseURL + "/api/v3")
+	return &gitLabClientV3{client: client}
+}
+
+// getGroupID returns the groupID
+func (c *gitLabClientV3) getGroupID(groupName string) (groupID int, err error) {
+
+	listGroupOpts := &gitlab.ListGroupsOptions{
+		Search: gitlab.String(groupName),
+	}
+
+	groups, _, err := c.client.Groups.ListGroups(listGroupOpts)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(groups) != 1 {
+		return 0, errors.New("can't find the specified group")
+	}
+
+	return groups[0].ID, nil
+}
+
+// listGroupProjects returns map with projectName and their ID
+func (c *gitLabClientV3) listGroupProjects(groupID int) (listProjects map[string]string, err error) {
+
+	options := &gitlab.ListGroupProjectsOptions{ListOptions: gitlab.ListOptions{}}
+
+	listProjects = make(map[string]string)
+	for {
+		projects, res, err := c.client.Groups.ListGroupProjects(groupID, options)
+		if err != nil {
+			return listProjects, err
+		}
+
+		for _, project := range projects {
+			listProjects[project.Name] = project.HTTPURLToRepo
+		}
+
+		if res.NextPage == 0 {
+			break
+		}
+		options.ListOptions.Page = res.NextPage
+	}
+
+	return listProjects, nil
+}
+
+// createGroupProject returns the projectURL
+func (c *gitLabClientV3) createGroupProject(projectName string, groupID int) (projectURL string, err error) {
+
+	project, _, err := c.client.Projects.CreateProject(&gitlab.CreateProjectOptions{
+		Name:        gitlab.String(projectName),
+		NamespaceID: gitlab.Int(groupID),
+		Visibility:  gitlab.Visibility(gitlab.InternalVisibility),
+	})
+	if err != nil {
+		return "", err
+	}
+	return project.HTTPURLToRepo, nil
+}
+
+// gitLabClientV4 talks to the current GitLab v4 REST API. Unlike v3, it
+// matches groups by FullPath equality rather than assuming Search returns
+// exactly one result, since sub-groups can share a name with a top-level
+// group; it also follows the X-Next-Page response header for pagination.
+type gitLabClientV4 struct {
+	client *gitlab.Client
+}
+
+func newGitLabClientV4(baseURL, privateToken string) *gitLabClientV4 {
+	client := gitlab.NewClient(nil, privateToken)
+	client.SetBaseURL(baseURL + "/api/v4")
+	return &gitLabClientV4{client: client}
+}
+
+func (c *gitLabClientV4) getGroupID(groupName string) (int, error) {
+
+	options := &gitlab.ListGroupsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: 100},
+		Search:      gitlab.String(groupName),
+	}
+
+	for {
+		groups, res, err := c.client.Groups.ListGroups(options)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, group := range groups {
+			if group.FullPath == groupName {
+				return group.ID, nil
+			}
+		}
+
+		page, err := nextPageFromHeader(res)
+		if err != nil {
+			return 0, err
+		}
+		if page == 0 {
+			break
+		}
+		options.ListOptions.Page = page
+	}
+
+	return 0, errors.New("can't find the specified group")
+}
+
+func (c *gitLabClientV4) listGroupProjects(groupID int) (map[string]string, error) {
+
+	options := &gitlab.ListGroupProjectsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+
+	listProjects := make(map[string]string)
+	for {
+		projects, res, err := c.client.Groups.ListGroupProjects(groupID, options)
+		if err != nil {
+			return listProjects, err
+		}
+
+		for _, project := range projects {
+			listProjects[project.Name] = project.HTTPURLToRepo
+		}
+
+		page, err := nextPageFromHeader(res)
+		if err != nil {
+			return listProjects, err
+		}
+		if page == 0 {
+			break
+		}
+		options.ListOptions.Page = page
+	}
+
+	return listProjects, nil
+}
+
+func (c *gitLabClientV4) createGroupProject(projectName string, groupID int) (string, error) {
+
+	project, _, err := c.client.Projects.CreateProject(&gitlab.CreateProjectOptions{
+		Name:        gitlab.String(projectName),
+		NamespaceID: gitlab.Int(groupID),
+		Visibility:  gitlab.Visibility(gitlab.InternalVisibility),
+	})
+	if err != nil {
+		return "", err
+	}
+	return project.HTTPURLToRepo, nil
+}
+
+// nextPageFromHeader extracts the X-Next-Page pagination header GitLab v4
+// sets on list responses, returning 0 once there are no further pages.
+func nextPageFromHeader(res *gitlab.Response) (int, error) {
+	next := res.Header.Get("X-Next-Page")
+	if next == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(next)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestNewGitLabAPIDispatchesByVersion(t *testing.T) {
+	v3, err := newGitLabAPI(gitLabAPIV3, "https://gitlab.example.com", "token")
+	if err != nil {
+		t.Fatalf("gitLabAPIV3: unexpected error: %v", err)
+	}
+	if _, ok := v3.(*gitLabClientV3); !ok {
+		t.Errorf("gitLabAPIV3 built a %T, want *gitLabClientV3", v3)
+	}
+
+	v4, err := newGitLabAPI(gitLabAPIV4, "https://gitlab.example.com", "token")
+	if err != nil {
+		t.Fatalf("gitLabAPIV4: unexpected error: %v", err)
+	}
+	if _, ok := v4.(*gitLabClientV4); !ok {
+		t.Errorf("gitLabAPIV4 built a %T, want *gitLabClientV4", v4)
+	}
+
+	if _, err := newGitLabAPI("bogus", "https://gitlab.example.com", "token"); err == nil {
+		t.Error("expected an error for an unknown --gitlab-api-version")
+	}
+}
+
+func TestNewGitLabAPIAutoDetectsV4(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v4/version" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := newGitLabAPI(gitLabAPIAuto, server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := client.(*gitLabClientV4); !ok {
+		t.Errorf("auto-detect against a v4 server built a %T, want *gitLabClientV4", client)
+	}
+}
+
+func TestNewGitLabAPIAutoFallsBackToV3(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, err := newGitLabAPI(gitLabAPIAuto, server.URL, "token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := client.(*gitLabClientV3); !ok {
+		t.Errorf("auto-detect against a non-v4 server built a %T, want *gitLabClientV3", client)
+	}
+}
+
+func TestNextPageFromHeader(t *testing.T) {
+	cases := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{name: "no header", header: "", want: 0},
+		{name: "next page", header: "3", want: 3},
+		{name: "malformed header", header: "not-a-number", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			header := http.Header{}
+			if c.header != "" {
+				header.Set("X-Next-Page", c.header)
+			}
+			res := &gitlab.Response{Response: &http.Response{Header: header}}
+
+			got, err := nextPageFromHeader(res)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got page %d", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got %d, want %d", got, c.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"errors"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -10,77 +10,25 @@ import (
 	"regexp"
 	"runtime"
 	"strings"
-	"time"
+	"sync"
 
-	"github.com/xanzy/go-gitlab"
+	"github.com/mmalchuk/glide-update/internal/importers"
+	"github.com/mmalchuk/glide-update/internal/importers/dep"
+	"github.com/mmalchuk/glide-update/internal/importers/glide"
+	"github.com/mmalchuk/glide-update/internal/importers/godep"
+	"github.com/mmalchuk/glide-update/internal/importers/vndr"
 	"gopkg.in/yaml.v2"
 )
 
-// Owner describes an owner of a package. This can be a person, company, or
-// other organization. This is useful if someone needs to contact the
-// owner of a package to address things like a security issue.
-type Owner struct {
-	// Name describes the name of an organization.
-	Name string `yaml:"name,omitempty"`
-
-	// Email is an email address to reach the owner at.
-	Email string `yaml:"email,omitempty"`
-
-	// Home is a url to a website for the owner.
-	Home string `yaml:"homepage,omitempty"`
-}
-
-// Owners is a list of owners for a project.
-type Owners []*Owner
-
-// Dependency describes a package that the present package depends upon.
-type Dependency struct {
-	Name        string   `yaml:"package"`
-	Reference   string   `yaml:"version,omitempty"`
-	Pin         string   `yaml:"-"`
-	Repository  string   `yaml:"repo,omitempty"`
-	VcsType     string   `yaml:"vcs,omitempty"`
-	Subpackages []string `yaml:"subpackages,omitempty"`
-	Arch        []string `yaml:"arch,omitempty"`
-	Os          []string `yaml:"os,omitempty"`
-}
-
-// Dependencies is a collection of Dependency
-type Dependencies []*Dependency
-
-// Config is a transitive representation of a dependency for importing and exporting to yaml.
-type Config struct {
-	Name        string       `yaml:"package"`
-	Description string       `yaml:"description,omitempty"`
-	Home        string       `yaml:"homepage,omitempty"`
-	License     string       `yaml:"license,omitempty"`
-	Owners      Owners       `yaml:"owners,omitempty"`
-	Ignore      []string     `yaml:"ignore,omitempty"`
-	Exclude     []string     `yaml:"excludeDirs,omitempty"`
-	Imports     Dependencies `yaml:"import"`
-	DevImports  Dependencies `yaml:"testImport,omitempty"`
-}
-
-// Lock represents an individual locked dependency.
-type Lock struct {
-	Name        string   `yaml:"name"`
-	Version     string   `yaml:"version"`
-	Repository  string   `yaml:"repo,omitempty"`
-	VcsType     string   `yaml:"vcs,omitempty"`
-	Subpackages []string `yaml:"subpackages,omitempty"`
-	Arch        []string `yaml:"arch,omitempty"`
-	Os          []string `yaml:"os,omitempty"`
-}
-
-// Locks is a slice of locked dependencies.
-type Locks []*Lock
-
-// Lockfile represents a glide.lock file.
-type Lockfile struct {
-	Hash       string    `yaml:"hash"`
-	Updated    time.Time `yaml:"updated"`
-	Imports    Locks     `yaml:"imports"`
-	DevImports Locks     `yaml:"testImports"`
+// userHome returns the current user's home directory, honouring Windows'
+// different environment variable the way glide itself does.
+func userHome() string {
+	home := os.Getenv("HOME")
+	if //noinspection GoBoolExpressions
+	runtime.GOOS == "windows" {
+		home = os.Getenv("USERPROFILE")
+	}
+	return home
 }
 
 func checkIfError(e error, msg ...string) {
@@ -94,278 +42,249 @@ func checkIfError(e error, msg ...string) {
 
 const (
 	glideYamlName string = "glide.yaml"
-	glideNewName  string = "glide.new"
-	glideLockName string = "glide.lock"
 )
 
-var (
-	out []byte
-)
+// processGlideCache pushes a single dependency's local glide cache up to
+// GitLab, or resolves it through a configured mirror. repository is the
+// dependency's recorded upstream URL, if the importer that discovered it
+// captured one; otherwise it's guessed from name. It is safe to call from
+// multiple goroutines: createMu serializes the check-then-create against
+// GitLab so two workers never race to create the same project. Under
+// dryRun it stops after recording what it would have done, touching
+// neither GitLab, the local cache, nor the cache's git remotes.
+func processGlideCache(client gitLabAPI, name string, repository string, projects map[string]string, glideCachePath string, groupID int, mirrors Mirrors, createMu *sync.Mutex, dryRun bool) (repoURL string, vcsType string, entry ReportEntry, err error) {
 
-// getGroupID returns the groupID
-func getGroupID(client *gitlab.Client, groupName string) (groupID int, err error) {
+	log.Printf("- processing '%s'", name)
 
-	listGroupOpts := &gitlab.ListGroupsOptions{
-		Search: gitlab.String(groupName),
+	reg, err := regexp.Compile("[/]+")
+	if err != nil {
+		return "", "", entry, err
 	}
+	// repository name on the filesystem
+	repoName := reg.ReplaceAllString(name, "-")
 
-	groups, _, err := client.Groups.ListGroups(listGroupOpts)
-
+	reg, err = regexp.Compile("[/.]+")
 	if err != nil {
-		return 0, err
+		return "", "", entry, err
 	}
+	// repository name on the server
+	safeRepoName := reg.ReplaceAllString(name, "-")
 
-	if len(groups) != 1 {
-		return 0, errors.New("can't find the specified group")
-	}
+	entry = ReportEntry{Name: name, SafeRepoName: safeRepoName}
 
-	return groups[0].ID, nil
-}
+	if mirror := mirrors.find(name); mirror != nil {
+		log.Printf("- '%s' is mirrored to '%s', skipping group project", name, mirror.Repo)
+		entry.Mirrored = true
+		entry.RemoteURL = mirror.Repo
+		return mirror.Repo, mirror.Vcs, entry, nil
+	}
 
-// listGroupProjects returns map with projectName and their ID
-func listGroupProjects(client *gitlab.Client, groupID int) (listProjects map[string]string, err error) {
+	localCacheRepo := glideCachePath + repoName
+	cloneURL := cloneURLFor(name, repository)
 
-	options := &gitlab.ListGroupProjectsOptions{ListOptions: gitlab.ListOptions{}}
+	existingURL, projectExisted := lookupGroupProject(projects, createMu, safeRepoName)
+	entry.ProjectExisted = projectExisted
 
-	listProjects = make(map[string]string)
-	for {
-		projects, res, err := client.Groups.ListGroupProjects(groupID, options)
-		if err != nil {
-			return listProjects, err
+	if dryRun {
+		if _, err := os.Stat(localCacheRepo); err != nil {
+			log.Printf("- dry-run: '%s' not cached locally, would clone '%s'", name, cloneURL)
+		} else {
+			entry.UpstreamURL = upstreamURL(localCacheRepo)
 		}
+		log.Printf("- dry-run: would mirror '%s' to group project '%s' (existing: %t)", name, safeRepoName, projectExisted)
+		entry.RemoteURL = existingURL
+		return existingURL, "", entry, nil
+	}
 
-		for _, project := range projects {
-			listProjects[project.Name] = project.HTTPURLToRepo
-		}
+	if err := ensureLocalCache(cloneURL, localCacheRepo); err != nil {
+		return "", "", entry, err
+	}
+	log.Printf("- found local cache repo '%s'", localCacheRepo)
+	entry.UpstreamURL = upstreamURL(localCacheRepo)
 
-		if res.NextPage == 0 {
-			break
-		}
-		options.ListOptions.Page = res.NextPage
+	remoteURL, err := ensureGroupProject(client, projects, createMu, safeRepoName, groupID)
+	if err != nil {
+		return "", "", entry, err
 	}
+	entry.RemoteURL = remoteURL
 
-	return listProjects, nil
-}
+	exec.Command("git", "-C", localCacheRepo, "remote", "remove", "upstream").Run()
 
-// createGroupProject returns the projectURL
-func createGroupProject(client *gitlab.Client, projectName string, groupID int) (projectURL string, err error) {
+	if out, err := exec.Command("git", "-C", localCacheRepo, "remote", "add", "upstream", remoteURL).CombinedOutput(); err != nil {
+		return "", "", entry, fmt.Errorf("git remote add: %v: %s", err, out)
+	}
 
-	project, _, err := client.Projects.CreateProject(&gitlab.CreateProjectOptions{
-		Name:        gitlab.String(projectName),
-		NamespaceID: gitlab.Int(groupID),
-		Visibility:  gitlab.Visibility(gitlab.InternalVisibility),
-	})
+	out, err := exec.Command("git", "-C", localCacheRepo, "push", "--all", "upstream").CombinedOutput()
 	if err != nil {
-		return "", err
+		return "", "", entry, fmt.Errorf("git push --all: %v: %s", err, out)
 	}
-	return project.HTTPURLToRepo, nil
-}
-
-func processGlideCache(client *gitlab.Client, name string, projects map[string]string, glideCachePath string, groupID int) string {
+	log.Printf("- push all branches:\n%s", out)
 
-	log.Printf("- processing '%s'", name)
+	out, err = exec.Command("git", "-C", localCacheRepo, "push", "--tags", "upstream").CombinedOutput()
+	if err != nil {
+		return "", "", entry, fmt.Errorf("git push --tags: %v: %s", err, out)
+	}
+	log.Printf("- push all tags:\n%s", out)
 
-	reg, err := regexp.Compile("[/]+")
-	checkIfError(err)
-	// repository name on the filesystem
-	repoName := reg.ReplaceAllString(name, "-")
+	log.Printf("- updated with upstream: '%s'", remoteURL)
+	log.Printf("")
 
-	reg, err = regexp.Compile("[/.]+")
-	checkIfError(err)
-	// repository name on the server
-	safeRepoName := reg.ReplaceAllString(name, "-")
+	return remoteURL, "", entry, nil
+}
 
-	remoteURL, projectExists := projects[safeRepoName]
+// cloneURLFor resolves the URL to clone name's source from: repository,
+// if the importer that discovered it recorded an explicit one (as the
+// vndr importer and a glide.lock repo entry do), otherwise https://<name>, the same
+// convention the local cache directory name itself encodes.
+func cloneURLFor(name, repository string) string {
+	if repository != "" {
+		return repository
+	}
+	return "https://" + name
+}
 
-	localCacheRepo := glideCachePath + repoName
+// ensureLocalCache makes sure localCacheRepo holds a clone of cloneURL,
+// cloning it the first time a dependency is mirrored. An already-cached
+// dependency is left untouched.
+func ensureLocalCache(cloneURL, localCacheRepo string) error {
 	if _, err := os.Stat(localCacheRepo); err == nil {
-		log.Printf("- found local cache repo '%s'", localCacheRepo)
+		return nil
+	}
 
-		if projectExists {
-			log.Printf("- remote repo '%s' already exists", remoteURL)
-		} else {
-			remoteURL, err = createGroupProject(client, safeRepoName, groupID)
-			if err == nil {
-				log.Printf("- remote repo '%s' created", remoteURL)
-			}
-		}
+	log.Printf("- cloning '%s' into local cache '%s'", cloneURL, localCacheRepo)
+	out, err := exec.Command("git", "clone", "--mirror", cloneURL, localCacheRepo).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git clone: %v: %s", err, out)
+	}
+	return nil
+}
 
-		out, err = exec.Command("git", "-C", localCacheRepo, "remote", "remove", "upstream").CombinedOutput()
-		out, err = exec.Command("git", "-C", localCacheRepo, "remote", "add", "upstream", remoteURL).CombinedOutput()
-		checkIfError(err, string(out))
+// upstreamURL resolves the URL localCacheRepo's origin remote points at,
+// i.e. the actual upstream the local glide cache was cloned from. Errors
+// are swallowed: this is report detail, not something worth aborting over.
+func upstreamURL(localCacheRepo string) string {
+	out, err := exec.Command("git", "-C", localCacheRepo, "remote", "get-url", "origin").CombinedOutput()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
 
-		out, err = exec.Command("git", "-C", localCacheRepo, "push", "--all", "upstream").CombinedOutput()
-		checkIfError(err, string(out))
-		log.Printf("- push all branches:\n%s", string(out))
+// lookupGroupProject reports the GitLab project URL already known for
+// safeRepoName, if any. projects is shared across worker goroutines, so
+// every access to it — reads included — goes through createMu.
+func lookupGroupProject(projects map[string]string, createMu *sync.Mutex, safeRepoName string) (remoteURL string, exists bool) {
+	createMu.Lock()
+	defer createMu.Unlock()
 
-		out, err = exec.Command("git", "-C", localCacheRepo, "push", "--tags", "upstream").CombinedOutput()
-		checkIfError(err, string(out))
-		log.Printf("- push all tags:\n%s", string(out))
+	remoteURL, exists = projects[safeRepoName]
+	return remoteURL, exists
+}
 
-		log.Printf("- updated with upstream: '%s'", remoteURL)
-		log.Printf("")
+// ensureGroupProject returns the URL of the GitLab project for
+// safeRepoName, creating it first if it doesn't already exist in
+// projects. createMu serializes this check-then-create across workers so
+// two of them can't both decide to create the same project.
+func ensureGroupProject(client gitLabAPI, projects map[string]string, createMu *sync.Mutex, safeRepoName string, groupID int) (string, error) {
+	createMu.Lock()
+	defer createMu.Unlock()
+
+	if remoteURL, ok := projects[safeRepoName]; ok {
+		log.Printf("- remote repo '%s' already exists", remoteURL)
+		return remoteURL, nil
+	}
 
-		return remoteURL
+	remoteURL, err := client.createGroupProject(safeRepoName, groupID)
+	if err != nil {
+		return "", err
 	}
-	return ""
+	log.Printf("- remote repo '%s' created", remoteURL)
+	projects[safeRepoName] = remoteURL
+	return remoteURL, nil
 }
 
+var (
+	mirrorsFlag          = flag.String("mirrors", "", "path to a mirrors.yaml file redirecting selected imports to pre-existing repos (default ~/.glide/mirrors.yaml)")
+	gitLabAPIVersionFlag = flag.String("gitlab-api-version", string(gitLabAPIAuto), "GitLab REST API version to use: v3, v4, or auto")
+	jobsFlag             = flag.Int("jobs", 4, "number of dependencies to mirror concurrently")
+	dryRunFlag           = flag.Bool("dry-run", false, "don't touch GitLab or push any git remotes, just report what would happen")
+	reportFlag           = flag.String("report", "", "write a YAML (or JSON, by .json extension) report of mirror actions to this path")
+)
+
 func main() {
 
-	cmdArgs := os.Args
-	if len(cmdArgs) == 1 {
-		log.Printf("Usage: %s <GitLabURL> <GitLabGroupName> <GitLabPrivateToken>", cmdArgs[0])
-		os.Exit(1)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <GitLabURL> <GitLabGroupName> <GitLabPrivateToken>\n", os.Args[0])
+		flag.PrintDefaults()
 	}
+	flag.Parse()
 
-	gitLabPrivateToken := cmdArgs[3]
-	gitLabGroup := cmdArgs[2]
-	gitLabURL := cmdArgs[1]
+	cmdArgs := flag.Args()
+	if len(cmdArgs) != 3 {
+		flag.Usage()
+		os.Exit(1)
+	}
 
-	gitLabClient := gitlab.NewClient(nil, gitLabPrivateToken)
-	gitLabClient.SetBaseURL(gitLabURL + "/api/v3")
+	gitLabURL := cmdArgs[0]
+	gitLabGroup := cmdArgs[1]
+	gitLabPrivateToken := cmdArgs[2]
 
-	gitLabGroupID, err := getGroupID(gitLabClient, gitLabGroup)
+	gitLabClient, err := newGitLabAPI(gitLabAPIVersion(*gitLabAPIVersionFlag), gitLabURL, gitLabPrivateToken)
 	if err != nil {
 		panic(err)
 	}
 
-	gitLabProjects, err := listGroupProjects(gitLabClient, gitLabGroupID)
+	gitLabGroupID, err := gitLabClient.getGroupID(gitLabGroup)
 	if err != nil {
 		panic(err)
 	}
 
-	home := os.Getenv("HOME")
-	if //noinspection GoBoolExpressions
-	runtime.GOOS == "windows" {
-		home = os.Getenv("USERPROFILE")
+	gitLabProjects, err := gitLabClient.listGroupProjects(gitLabGroupID)
+	if err != nil {
+		panic(err)
 	}
-	glideCachePath := home + "/.glide/cache/src/https-"
-
-	var glideConfig Config
-	var ignoreBlock []string
 
-	// clear glide cache
-	out, err = exec.Command("glide", "--no-color", "cache-clear").CombinedOutput()
-	checkIfError(err, string(out))
-	log.Printf("Executing 'glide cache-clear':\n%s", string(out))
-
-	// remove new glide config
-	log.Printf("Removing '%s' file if exists...", glideNewName)
-	os.Remove(glideNewName)
-
-	// create new glide config from sources
-	out, err = exec.Command("glide", "--no-color", "--yaml", glideNewName, "init", "--non-interactive").CombinedOutput()
-	checkIfError(err, string(out))
-	log.Printf("Executing 'glide --yaml %s init --non-interactive' ...\n%s", glideNewName, string(out))
+	mirrors, err := loadMirrors(*mirrorsFlag)
+	if err != nil {
+		panic(err)
+	}
 
-	// read created glide config
-	log.Printf("Reading '%s' file...", glideNewName)
-	glideYaml, err := ioutil.ReadFile(glideNewName)
-	checkIfError(err)
+	glideCachePath := userHome() + "/.glide/cache/src/https-"
 
-	log.Printf("Parsing newly created file...")
-	err = yaml.Unmarshal(glideYaml, &glideConfig)
+	log.Printf("Resolving dependencies...")
+	composite := importers.NewCompositeImporter(
+		glide.NewImporter(),
+		godep.NewImporter(),
+		dep.NewImporter(),
+		vndr.NewImporter(),
+	)
+	glideConfig, locks, err := composite.Import(".")
 	checkIfError(err)
-
-	// remove own reference from the imports block
-	newImports := Dependencies{}
-	for _, pkg := range glideConfig.Imports {
-		if strings.HasPrefix(pkg.Name, glideConfig.Name) {
-			ignoreBlock = append(ignoreBlock, pkg.Name)
-		} else {
-			newImport := Dependency{
-				Name:        pkg.Name,
-				Subpackages: pkg.Subpackages,
-			}
-			newImports = append(newImports, &newImport)
-		}
+	if len(locks.Imports)+len(locks.DevImports) == 0 {
+		log.Printf("- no dependencies found by any importer (manifest-based or source-tree discovery); writing an empty %s", glideYamlName)
 	}
-	glideConfig.Imports = newImports
 
-	// remove own reference from the devImports block
-	devImports := Dependencies{}
-	for _, pkg := range glideConfig.DevImports {
-		if strings.HasPrefix(pkg.Name, glideConfig.Name) {
-			ignoreBlock = append(ignoreBlock, pkg.Name)
-		} else {
-			devImport := Dependency{
-				Name:        pkg.Name,
-				Subpackages: pkg.Subpackages,
-			}
-			devImports = append(devImports, &devImport)
-		}
+	concurrentWorkers := *jobsFlag
+	if concurrentWorkers < 1 {
+		concurrentWorkers = 1
 	}
-	glideConfig.DevImports = devImports
 
-	// ignored imports
-	glideConfig.Ignore = ignoreBlock
+	var report *Report
+	glideConfig.Imports, glideConfig.DevImports, report = mirrorAll(gitLabClient, gitLabProjects, glideCachePath, gitLabGroupID, mirrors, locks, concurrentWorkers, *dryRunFlag)
+	imported := len(glideConfig.Imports) + len(glideConfig.DevImports)
 
-	// create new glide config file
-	out, err = yaml.Marshal(&glideConfig)
-	checkIfError(err)
-	err = ioutil.WriteFile(glideYamlName, out, 0644)
-	checkIfError(err)
-
-	log.Printf("Recreated '%s' file...", glideYamlName)
-
-	// remove new glide config
-	log.Printf("Removing '%s' file...", glideNewName)
-	os.Remove(glideNewName)
-
-	// remove glide lock file
-	log.Printf("Removing '%s' file if exists...", glideLockName)
-	os.Remove(glideLockName)
-
-	// remove vendor directory with contents
-	log.Printf("Purging 'vendor' directory...")
-	os.RemoveAll("vendor")
-
-	// import packages with glide
-	out, err = exec.Command("glide", "--no-color", "--debug", "install", "--strip-vendor").CombinedOutput()
-	checkIfError(err, string(out))
-	log.Printf("Executing 'glide install --strip-vendor' ...\n%s", string(out))
-
-	log.Printf("Reading '%s' file...", glideLockName)
-	glideLock, err := ioutil.ReadFile(glideLockName)
-	checkIfError(err)
-
-	var locks Lockfile
-	log.Printf("Parsing file...")
-	err = yaml.Unmarshal(glideLock, &locks)
-	checkIfError(err)
-
-	glideConfig.Imports = Dependencies{}
-	for _, pkg := range locks.Imports {
-		remoteURL := processGlideCache(gitLabClient, pkg.Name, gitLabProjects, glideCachePath, gitLabGroupID)
-		if remoteURL != "" {
-			dep := Dependency{
-				Name:        pkg.Name,
-				Reference:   pkg.Version,
-				Repository:  remoteURL,
-				Subpackages: pkg.Subpackages,
-			}
-			glideConfig.Imports = append(glideConfig.Imports, &dep)
-		}
+	if *reportFlag != "" {
+		report.DryRun = *dryRunFlag
+		checkIfError(writeReport(*reportFlag, report))
+		log.Printf("Wrote report to '%s'.", *reportFlag)
 	}
-	imported := len(glideConfig.Imports)
-
-	glideConfig.DevImports = Dependencies{}
-	for _, pkg := range locks.DevImports {
-		remoteURL := processGlideCache(gitLabClient, pkg.Name, gitLabProjects, glideCachePath, gitLabGroupID)
-		if remoteURL != "" {
-			dep := Dependency{
-				Name:        pkg.Name,
-				Reference:   pkg.Version,
-				Repository:  remoteURL,
-				Subpackages: pkg.Subpackages,
-			}
-			glideConfig.DevImports = append(glideConfig.DevImports, &dep)
-		}
+
+	if *dryRunFlag {
+		log.Printf("Dry-run: not writing %s (%d dependencies examined, see --report for detail).", glideYamlName, len(report.Imports)+len(report.DevImports))
+		return
 	}
-	imported = imported + len(glideConfig.DevImports)
 
-	out, err = yaml.Marshal(&glideConfig)
+	out, err := yaml.Marshal(glideConfig)
 	checkIfError(err)
 	err = ioutil.WriteFile(glideYamlName, out, 0644)
 	checkIfError(err)
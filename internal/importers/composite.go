@@ -0,0 +1,138 @@
+// Package importers defines a common Importer interface for legacy Go
+// dependency manifests, plus a CompositeImporter that tries several of
+// them in order and merges the results into a single Config/Lockfile.
+package importers
+
+import "github.com/mmalchuk/glide-update/internal/importers/glide"
+
+// Importer can detect and import a legacy dependency manifest format,
+// producing the Config/Lockfile shape the rest of the tool works with,
+// regardless of the manifest's original format.
+type Importer interface {
+	// Detect reports whether dir contains this importer's manifest.
+	Detect(dir string) bool
+	// Import parses dir's manifest into a Config and Lockfile.
+	Import(dir string) (*glide.Config, *glide.Lockfile, error)
+}
+
+// CompositeImporter tries a fixed, deterministic sequence of Importers and
+// merges their results: later importers overlay dependencies on earlier
+// ones, except where an earlier importer already pinned an explicit
+// version, which is preserved.
+type CompositeImporter struct {
+	importers []Importer
+}
+
+// NewCompositeImporter returns a CompositeImporter that tries importers in
+// the given order.
+func NewCompositeImporter(importers ...Importer) *CompositeImporter {
+	return &CompositeImporter{importers: importers}
+}
+
+// Import runs every Importer that detects a manifest in dir and merges
+// their results into a single Config and Lockfile.
+func (c *CompositeImporter) Import(dir string) (*glide.Config, *glide.Lockfile, error) {
+	config := &glide.Config{}
+	imports := newDepMerger()
+	devImports := newDepMerger()
+	locks := newLockMerger()
+	devLocks := newLockMerger()
+
+	for _, importer := range c.importers {
+		if !importer.Detect(dir) {
+			continue
+		}
+
+		importedConfig, importedLock, err := importer.Import(dir)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if config.Name == "" {
+			config.Name = importedConfig.Name
+		}
+
+		imports.merge(importedConfig.Imports)
+		devImports.merge(importedConfig.DevImports)
+		locks.merge(importedLock.Imports)
+		devLocks.merge(importedLock.DevImports)
+	}
+
+	config.Imports = imports.dependencies()
+	config.DevImports = devImports.dependencies()
+
+	lock := &glide.Lockfile{
+		Imports:    locks.locks(),
+		DevImports: devLocks.locks(),
+	}
+
+	return config, lock, nil
+}
+
+// depMerger accumulates Dependency entries by name in first-seen order,
+// overlaying later entries onto earlier ones unless the earlier entry
+// already carried an explicit version pin.
+type depMerger struct {
+	order  []string
+	byName map[string]*glide.Dependency
+}
+
+func newDepMerger() *depMerger {
+	return &depMerger{byName: map[string]*glide.Dependency{}}
+}
+
+func (m *depMerger) merge(deps glide.Dependencies) {
+	for _, dep := range deps {
+		prior, ok := m.byName[dep.Name]
+		if !ok {
+			m.order = append(m.order, dep.Name)
+			m.byName[dep.Name] = dep
+			continue
+		}
+		if prior.Reference != "" {
+			continue
+		}
+		m.byName[dep.Name] = dep
+	}
+}
+
+func (m *depMerger) dependencies() glide.Dependencies {
+	deps := make(glide.Dependencies, 0, len(m.order))
+	for _, name := range m.order {
+		deps = append(deps, m.byName[name])
+	}
+	return deps
+}
+
+// lockMerger is depMerger's Lock-shaped counterpart.
+type lockMerger struct {
+	order  []string
+	byName map[string]*glide.Lock
+}
+
+func newLockMerger() *lockMerger {
+	return &lockMerger{byName: map[string]*glide.Lock{}}
+}
+
+func (m *lockMerger) merge(locks glide.Locks) {
+	for _, lock := range locks {
+		prior, ok := m.byName[lock.Name]
+		if !ok {
+			m.order = append(m.order, lock.Name)
+			m.byName[lock.Name] = lock
+			continue
+		}
+		if prior.Version != "" {
+			continue
+		}
+		m.byName[lock.Name] = lock
+	}
+}
+
+func (m *lockMerger) locks() glide.Locks {
+	locks := make(glide.Locks, 0, len(m.order))
+	for _, name := range m.order {
+		locks = append(locks, m.byName[name])
+	}
+	return locks
+}
@@ -0,0 +1,99 @@
+package importers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mmalchuk/glide-update/internal/importers/glide"
+)
+
+// fakeImporter is a stub Importer driven entirely by its fields, so
+// CompositeImporter's merge/overlay logic can be tested without touching
+// disk.
+type fakeImporter struct {
+	detected bool
+	config   *glide.Config
+	lock     *glide.Lockfile
+}
+
+func (f *fakeImporter) Detect(dir string) bool {
+	return f.detected
+}
+
+func (f *fakeImporter) Import(dir string) (*glide.Config, *glide.Lockfile, error) {
+	return f.config, f.lock, nil
+}
+
+func TestCompositeImporterSkipsUndetectedImporters(t *testing.T) {
+	skipped := &fakeImporter{detected: false, config: &glide.Config{
+		Imports: glide.Dependencies{{Name: "should/not/appear"}},
+	}, lock: &glide.Lockfile{}}
+	used := &fakeImporter{detected: true, config: &glide.Config{
+		Name:    "example.com/project",
+		Imports: glide.Dependencies{{Name: "example.com/foo"}},
+	}, lock: &glide.Lockfile{Imports: glide.Locks{{Name: "example.com/foo", Version: "v1"}}}}
+
+	composite := NewCompositeImporter(skipped, used)
+
+	config, lock, err := composite.Import(".")
+	if err != nil {
+		t.Fatalf("Import() returned error: %v", err)
+	}
+
+	if config.Name != "example.com/project" {
+		t.Errorf("config.Name = %q, want %q", config.Name, "example.com/project")
+	}
+	if len(config.Imports) != 1 || config.Imports[0].Name != "example.com/foo" {
+		t.Errorf("config.Imports = %v, want only example.com/foo", config.Imports)
+	}
+	if len(lock.Imports) != 1 || lock.Imports[0].Version != "v1" {
+		t.Errorf("lock.Imports = %v, want example.com/foo pinned to v1", lock.Imports)
+	}
+}
+
+func TestCompositeImporterOverlayPreservesExplicitVersionPins(t *testing.T) {
+	earlier := &fakeImporter{detected: true, config: &glide.Config{
+		Imports: glide.Dependencies{{Name: "example.com/pinned", Reference: "v1.2.3"}},
+	}, lock: &glide.Lockfile{Imports: glide.Locks{{Name: "example.com/pinned", Version: "v1.2.3"}}}}
+	later := &fakeImporter{detected: true, config: &glide.Config{
+		Imports: glide.Dependencies{{Name: "example.com/pinned"}},
+	}, lock: &glide.Lockfile{Imports: glide.Locks{{Name: "example.com/pinned"}}}}
+
+	composite := NewCompositeImporter(earlier, later)
+
+	config, lock, err := composite.Import(".")
+	if err != nil {
+		t.Fatalf("Import() returned error: %v", err)
+	}
+
+	if len(config.Imports) != 1 || config.Imports[0].Reference != "v1.2.3" {
+		t.Errorf("config.Imports = %v, want the earlier importer's v1.2.3 pin preserved", config.Imports)
+	}
+	if len(lock.Imports) != 1 || lock.Imports[0].Version != "v1.2.3" {
+		t.Errorf("lock.Imports = %v, want the earlier importer's v1.2.3 pin preserved", lock.Imports)
+	}
+}
+
+func TestCompositeImporterOverlayFillsInUnpinnedDependencies(t *testing.T) {
+	earlier := &fakeImporter{detected: true, config: &glide.Config{
+		Imports: glide.Dependencies{{Name: "example.com/unpinned"}},
+	}, lock: &glide.Lockfile{Imports: glide.Locks{{Name: "example.com/unpinned"}}}}
+	later := &fakeImporter{detected: true, config: &glide.Config{
+		Imports: glide.Dependencies{{Name: "example.com/unpinned", Reference: "deadbeef"}},
+	}, lock: &glide.Lockfile{Imports: glide.Locks{{Name: "example.com/unpinned", Version: "deadbeef"}}}}
+
+	composite := NewCompositeImporter(earlier, later)
+
+	config, lock, err := composite.Import(".")
+	if err != nil {
+		t.Fatalf("Import() returned error: %v", err)
+	}
+
+	want := glide.Dependencies{{Name: "example.com/unpinned", Reference: "deadbeef"}}
+	if !reflect.DeepEqual(config.Imports, want) {
+		t.Errorf("config.Imports = %+v, want %+v (later importer's version should fill in the gap)", config.Imports, want)
+	}
+	if len(lock.Imports) != 1 || lock.Imports[0].Version != "deadbeef" {
+		t.Errorf("lock.Imports = %v, want example.com/unpinned pinned to deadbeef", lock.Imports)
+	}
+}
@@ -0,0 +1,88 @@
+// Package dep imports dependencies from Gopkg.lock, the lockfile written
+// by golang/dep. Gopkg.toml itself only carries constraints rather than
+// resolved versions, so only the lock is consulted here.
+package dep
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mmalchuk/glide-update/internal/importers/glide"
+)
+
+// Importer reads Gopkg.lock.
+type Importer struct{}
+
+// NewImporter returns an Importer ready to use.
+func NewImporter() *Importer {
+	return &Importer{}
+}
+
+// Detect reports whether dir has a Gopkg.lock manifest.
+func (i *Importer) Detect(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "Gopkg.lock"))
+	return err == nil
+}
+
+// Import parses dir's Gopkg.lock [[projects]] blocks into a Config and
+// Lockfile. It is a minimal line-oriented reader of the handful of TOML
+// keys glide-update cares about, not a general TOML parser.
+func (i *Importer) Import(dir string) (*glide.Config, *glide.Lockfile, error) {
+	file, err := os.Open(filepath.Join(dir, "Gopkg.lock"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	config := &glide.Config{}
+	lock := &glide.Lockfile{}
+
+	var name, revision, version string
+	flush := func() {
+		if name == "" {
+			return
+		}
+		ref := version
+		if ref == "" {
+			ref = revision
+		}
+		config.Imports = append(config.Imports, &glide.Dependency{Name: name, Reference: ref})
+		lock.Imports = append(lock.Imports, &glide.Lock{Name: name, Version: ref})
+		name, revision, version = "", "", ""
+	}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "[[projects]]":
+			flush()
+		case strings.HasPrefix(line, "name ="):
+			name = tomlStringValue(line)
+		case strings.HasPrefix(line, "revision ="):
+			revision = tomlStringValue(line)
+		case strings.HasPrefix(line, "version ="):
+			version = tomlStringValue(line)
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return config, lock, nil
+}
+
+// tomlStringValue extracts the double-quoted value out of a
+// `key = "value"` TOML line.
+func tomlStringValue(line string) string {
+	start := strings.IndexByte(line, '"')
+	end := strings.LastIndexByte(line, '"')
+	if start < 0 || end <= start {
+		return ""
+	}
+	return line[start+1 : end]
+}
@@ -0,0 +1,30 @@
+package glide
+
+// Importer adapts Resolver to the shape importers.CompositeImporter
+// expects, so glide manifests (or, absent one, source-tree discovery) can
+// be tried alongside godep, dep, and vndr.
+type Importer struct {
+	resolver *Resolver
+}
+
+// NewImporter returns an Importer ready to use.
+func NewImporter() *Importer {
+	return &Importer{resolver: NewResolver()}
+}
+
+// Detect always reports true. Unlike the other importers, Resolver.Resolve
+// doesn't need a glide.yaml/glide.lock to produce a usable Config and
+// Lockfile: absent either file, it discovers imports by walking dir's
+// source tree itself. That makes the glide importer CompositeImporter's
+// catch-all, so a project with no manifest at all still gets whatever
+// imports it actually has, rather than being silently treated as having
+// zero dependencies.
+func (i *Importer) Detect(dir string) bool {
+	return true
+}
+
+// Import resolves dir's glide manifest via Resolver, falling back to
+// source-tree discovery if no manifest is present.
+func (i *Importer) Import(dir string) (*Config, *Lockfile, error) {
+	return i.resolver.Resolve(dir)
+}
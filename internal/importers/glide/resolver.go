@@ -0,0 +1,190 @@
+package glide
+
+import (
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Resolver discovers a project's glide Config and Lockfile directly from
+// disk, without shelling out to the glide binary.
+type Resolver struct{}
+
+// NewResolver returns a Resolver ready to use.
+func NewResolver() *Resolver {
+	return &Resolver{}
+}
+
+// Resolve returns the Config and Lockfile for the project rooted at
+// projectDir. If glide.yaml/glide.lock are present they're parsed as-is;
+// otherwise Resolve synthesizes both by walking the source tree for
+// imports, the way `glide init --non-interactive` followed by
+// `glide install` would have.
+func (r *Resolver) Resolve(projectDir string) (*Config, *Lockfile, error) {
+	config, err := r.readOrDiscoverConfig(projectDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lock, err := r.readOrSynthesizeLockfile(projectDir, config)
+	if err != nil {
+		return config, nil, err
+	}
+
+	return config, lock, nil
+}
+
+func (r *Resolver) readOrDiscoverConfig(projectDir string) (*Config, error) {
+	data, err := ioutil.ReadFile(filepath.Join(projectDir, "glide.yaml"))
+	if err == nil {
+		var config Config
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			return nil, err
+		}
+		return &config, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return discoverConfig(projectDir)
+}
+
+func (r *Resolver) readOrSynthesizeLockfile(projectDir string, config *Config) (*Lockfile, error) {
+	data, err := ioutil.ReadFile(filepath.Join(projectDir, "glide.lock"))
+	if err == nil {
+		var lock Lockfile
+		if err := yaml.Unmarshal(data, &lock); err != nil {
+			return nil, err
+		}
+		return &lock, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return synthesizeLockfile(config), nil
+}
+
+// synthesizeLockfile converts a Config's imports into a Lockfile with no
+// resolved version, mirroring the shape `glide install` would produce
+// before it has fetched anything.
+func synthesizeLockfile(config *Config) *Lockfile {
+	lock := &Lockfile{}
+	for _, dep := range config.Imports {
+		lock.Imports = append(lock.Imports, &Lock{
+			Name:        dep.Name,
+			Repository:  dep.Repository,
+			VcsType:     dep.VcsType,
+			Subpackages: dep.Subpackages,
+		})
+	}
+	for _, dep := range config.DevImports {
+		lock.DevImports = append(lock.DevImports, &Lock{
+			Name:        dep.Name,
+			Repository:  dep.Repository,
+			VcsType:     dep.VcsType,
+			Subpackages: dep.Subpackages,
+		})
+	}
+	return lock
+}
+
+// discoverConfig walks projectDir's Go source, collecting every imported
+// package outside of the standard library and the project itself. Imports
+// belonging to the project itself are recorded in Config.Ignore, the same
+// role glide's own `ignore` block plays.
+func discoverConfig(projectDir string) (*Config, error) {
+	pkg, err := build.ImportDir(projectDir, 0)
+	if err != nil {
+		if _, ok := err.(*build.NoGoError); !ok {
+			return nil, err
+		}
+	}
+
+	fset := token.NewFileSet()
+	imports := map[string]bool{}
+	ignored := map[string]bool{}
+
+	walkErr := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == "vendor" || info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+
+		for _, imp := range file.Imports {
+			importPath := strings.Trim(imp.Path.Value, `"`)
+			if isStandardLibrary(importPath) {
+				continue
+			}
+			if pkg.ImportPath != "" && strings.HasPrefix(importPath, pkg.ImportPath) {
+				ignored[importPath] = true
+				continue
+			}
+			imports[repoRoot(importPath)] = true
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	config := &Config{Name: pkg.ImportPath}
+	for _, importPath := range sortedKeys(imports) {
+		config.Imports = append(config.Imports, &Dependency{Name: importPath})
+	}
+	config.Ignore = sortedKeys(ignored)
+
+	return config, nil
+}
+
+// sortedKeys returns the keys of set in ascending order, so callers that
+// feed a discovered config into a written glide.yaml get stable output
+// across runs instead of Go's randomized map iteration order.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isStandardLibrary reports whether importPath belongs to the standard
+// library, i.e. its first path element has no dot in it.
+func isStandardLibrary(importPath string) bool {
+	first := strings.SplitN(importPath, "/", 2)[0]
+	return !strings.Contains(first, ".")
+}
+
+// repoRoot trims an import path down to what is, for the common code
+// hosts, its repository root, e.g. "github.com/foo/bar/baz" becomes
+// "github.com/foo/bar". This is a simplification of glide's own, more
+// thorough VCS-root detection.
+func repoRoot(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	if len(parts) <= 3 {
+		return importPath
+	}
+	return strings.Join(parts[:3], "/")
+}
@@ -0,0 +1,60 @@
+package glide
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolverResolveReadsGlideYAMLAndLock(t *testing.T) {
+	resolver := NewResolver()
+
+	config, lock, err := resolver.Resolve("testdata/fixture")
+	if err != nil {
+		t.Fatalf("Resolve() returned error: %v", err)
+	}
+
+	if config.Name != "github.com/example/fixture" {
+		t.Errorf("config.Name = %q, want %q", config.Name, "github.com/example/fixture")
+	}
+
+	var gotImports []string
+	for _, dep := range config.Imports {
+		gotImports = append(gotImports, dep.Name)
+	}
+	wantImports := []string{"github.com/foo/bar"}
+	if !reflect.DeepEqual(gotImports, wantImports) {
+		t.Errorf("config.Imports = %v, want %v", gotImports, wantImports)
+	}
+
+	if len(lock.Imports) != 1 || lock.Imports[0].Name != "github.com/foo/bar" || lock.Imports[0].Version != "deadbeef" {
+		t.Errorf("lock.Imports = %+v, want a single github.com/foo/bar pinned to deadbeef", lock.Imports)
+	}
+}
+
+func TestResolverResolveFallsBackToDiscoveryAndSorts(t *testing.T) {
+	resolver := NewResolver()
+
+	config, lock, err := resolver.Resolve("testdata/discover")
+	if err != nil {
+		t.Fatalf("Resolve() returned error: %v", err)
+	}
+
+	var gotImports []string
+	for _, dep := range config.Imports {
+		gotImports = append(gotImports, dep.Name)
+	}
+	want := []string{"aaa.example.com/aaa/sub", "zzz.example.com/zzz/sub"}
+	if !reflect.DeepEqual(gotImports, want) {
+		t.Errorf("config.Imports = %v, want %v (sorted)", gotImports, want)
+	}
+
+	// With no glide.lock present, Resolve synthesizes one from the
+	// discovered config, so repeated runs still produce the same order.
+	var gotLockNames []string
+	for _, dep := range lock.Imports {
+		gotLockNames = append(gotLockNames, dep.Name)
+	}
+	if !reflect.DeepEqual(gotLockNames, want) {
+		t.Errorf("lock.Imports = %v, want %v (sorted)", gotLockNames, want)
+	}
+}
@@ -0,0 +1,7 @@
+package discover
+
+import (
+	_ "zzz.example.com/zzz/sub"
+
+	_ "aaa.example.com/aaa/sub"
+)
@@ -0,0 +1,57 @@
+// Package godep imports dependencies from Godeps/Godeps.json, the manifest
+// format written by the legacy github.com/tools/godep.
+package godep
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/mmalchuk/glide-update/internal/importers/glide"
+)
+
+// manifest mirrors the subset of Godeps.json this importer reads.
+type manifest struct {
+	ImportPath string `json:"ImportPath"`
+	Deps       []struct {
+		ImportPath string `json:"ImportPath"`
+		Rev        string `json:"Rev"`
+	} `json:"Deps"`
+}
+
+// Importer reads Godeps/Godeps.json.
+type Importer struct{}
+
+// NewImporter returns an Importer ready to use.
+func NewImporter() *Importer {
+	return &Importer{}
+}
+
+// Detect reports whether dir has a Godeps/Godeps.json manifest.
+func (i *Importer) Detect(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "Godeps", "Godeps.json"))
+	return err == nil
+}
+
+// Import parses dir's Godeps/Godeps.json into a Config and Lockfile.
+func (i *Importer) Import(dir string) (*glide.Config, *glide.Lockfile, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "Godeps", "Godeps.json"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, nil, err
+	}
+
+	config := &glide.Config{Name: m.ImportPath}
+	lock := &glide.Lockfile{}
+	for _, dep := range m.Deps {
+		config.Imports = append(config.Imports, &glide.Dependency{Name: dep.ImportPath, Reference: dep.Rev})
+		lock.Imports = append(lock.Imports, &glide.Lock{Name: dep.ImportPath, Version: dep.Rev})
+	}
+
+	return config, lock, nil
+}
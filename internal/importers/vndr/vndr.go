@@ -0,0 +1,66 @@
+// Package vndr imports dependencies from vendor.conf, the manifest format
+// used by LK4D4/vndr.
+package vndr
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mmalchuk/glide-update/internal/importers/glide"
+)
+
+// Importer reads vendor.conf.
+type Importer struct{}
+
+// NewImporter returns an Importer ready to use.
+func NewImporter() *Importer {
+	return &Importer{}
+}
+
+// Detect reports whether dir has a vendor.conf manifest.
+func (i *Importer) Detect(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "vendor.conf"))
+	return err == nil
+}
+
+// Import parses dir's vendor.conf into a Config and Lockfile. Each
+// non-comment line is "<import path> <revision> [<repo url>]".
+func (i *Importer) Import(dir string) (*glide.Config, *glide.Lockfile, error) {
+	file, err := os.Open(filepath.Join(dir, "vendor.conf"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	config := &glide.Config{}
+	lock := &glide.Lockfile{}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, rev := fields[0], fields[1]
+
+		dep := &glide.Dependency{Name: name, Reference: rev}
+		if len(fields) > 2 {
+			dep.Repository = fields[2]
+		}
+		config.Imports = append(config.Imports, dep)
+		lock.Imports = append(lock.Imports, &glide.Lock{Name: name, Version: rev, Repository: dep.Repository})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return config, lock, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Mirror describes a single redirect from an upstream package name to a
+// pre-existing repository. When a package matches a Mirror's Original
+// field, the tool skips creating/pushing a group project for it and emits
+// Repo (and, if set, Vcs) straight into the rewritten glide.yaml instead.
+type Mirror struct {
+	Original string `yaml:"original"`
+	Repo     string `yaml:"repo"`
+	Vcs      string `yaml:"vcs,omitempty"`
+}
+
+// Mirrors is a collection of Mirror entries, in the order they appear in
+// mirrors.yaml.
+type Mirrors []*Mirror
+
+// mirrorsFile is the root document of a mirrors.yaml file.
+type mirrorsFile struct {
+	Mirrors Mirrors `yaml:"mirrors"`
+}
+
+// defaultMirrorsPath returns the conventional location of mirrors.yaml,
+// alongside glide's own cache under the user's home directory.
+func defaultMirrorsPath() string {
+	return userHome() + "/.glide/mirrors.yaml"
+}
+
+// loadMirrors reads and parses a mirrors.yaml file. If path is empty,
+// defaultMirrorsPath is used instead. A missing file is not an error: it
+// simply means no mirrors are configured.
+func loadMirrors(path string) (Mirrors, error) {
+	if path == "" {
+		path = defaultMirrorsPath()
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var doc mirrorsFile
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc.Mirrors, nil
+}
+
+// find returns the Mirror configured for the given package name, or nil if
+// none matches.
+func (m Mirrors) find(name string) *Mirror {
+	for _, mirror := range m {
+		if mirror.Original == name {
+			return mirror
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestMirrorsFind(t *testing.T) {
+	mirrors := Mirrors{
+		{Original: "github.com/foo/bar", Repo: "https://gitlab.example.com/mirrors/bar", Vcs: "git"},
+		{Original: "github.com/foo/baz", Repo: "https://gitlab.example.com/mirrors/baz"},
+	}
+
+	if mirror := mirrors.find("github.com/foo/bar"); mirror == nil || mirror.Repo != "https://gitlab.example.com/mirrors/bar" {
+		t.Errorf("find(bar) = %+v, want the bar mirror", mirror)
+	}
+
+	if mirror := mirrors.find("github.com/foo/baz"); mirror == nil || mirror.Vcs != "" {
+		t.Errorf("find(baz) = %+v, want the baz mirror with no Vcs override", mirror)
+	}
+
+	if mirror := mirrors.find("github.com/not/mirrored"); mirror != nil {
+		t.Errorf("find(not/mirrored) = %+v, want nil", mirror)
+	}
+}
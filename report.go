@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ReportEntry summarizes what mirrorAll did, or would do under --dry-run,
+// for a single dependency.
+type ReportEntry struct {
+	Name           string `json:"name" yaml:"name"`
+	Version        string `json:"version,omitempty" yaml:"version,omitempty"`
+	SafeRepoName   string `json:"safeRepoName,omitempty" yaml:"safeRepoName,omitempty"`
+	UpstreamURL    string `json:"upstreamUrl,omitempty" yaml:"upstreamUrl,omitempty"`
+	RemoteURL      string `json:"remoteUrl,omitempty" yaml:"remoteUrl,omitempty"`
+	ProjectExisted bool   `json:"projectExisted" yaml:"projectExisted"`
+	Mirrored       bool   `json:"mirrored" yaml:"mirrored"`
+	Error          string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Report is the document written by --report: one entry per dependency
+// glide-update processed, for auditing in CI or reviewing before a run
+// that actually touches GitLab.
+type Report struct {
+	DryRun     bool          `json:"dryRun" yaml:"dryRun"`
+	Imports    []ReportEntry `json:"imports,omitempty" yaml:"imports,omitempty"`
+	DevImports []ReportEntry `json:"devImports,omitempty" yaml:"devImports,omitempty"`
+}
+
+// writeReport marshals report to path, choosing JSON or YAML by its file
+// extension (defaulting to YAML).
+func writeReport(path string, report *Report) error {
+	var data []byte
+	var err error
+	if strings.HasSuffix(path, ".json") {
+		data, err = json.MarshalIndent(report, "", "  ")
+	} else {
+		data, err = yaml.Marshal(report)
+	}
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestWriteReportYAML(t *testing.T) {
+	report := &Report{
+		DryRun:  true,
+		Imports: []ReportEntry{{Name: "github.com/foo/bar", Version: "v1.0.0", RemoteURL: "https://gitlab.example.com/foo-bar"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.yaml")
+	if err := writeReport(path, report); err != nil {
+		t.Fatalf("writeReport() returned error: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() returned error: %v", err)
+	}
+
+	var got Report
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("yaml.Unmarshal() returned error: %v", err)
+	}
+	if got.DryRun != report.DryRun || len(got.Imports) != 1 || got.Imports[0].Name != "github.com/foo/bar" {
+		t.Errorf("round-tripped report = %+v, want %+v", got, *report)
+	}
+}
+
+func TestWriteReportJSON(t *testing.T) {
+	report := &Report{
+		Imports: []ReportEntry{{Name: "github.com/foo/bar", Error: "boom"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := writeReport(path, report); err != nil {
+		t.Fatalf("writeReport() returned error: %v", err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() returned error: %v", err)
+	}
+
+	var got Report
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() returned error: %v", err)
+	}
+	if len(got.Imports) != 1 || got.Imports[0].Error != "boom" {
+		t.Errorf("round-tripped report = %+v, want Imports[0].Error = %q", got, "boom")
+	}
+}